@@ -0,0 +1,34 @@
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizePrefixesHeadingID(t *testing.T) {
+	input := `<h2 id="section-2">Title</h2>`
+	output := Sanitize("https://example.org/", input)
+	if !strings.Contains(output, `id="yarr-section-2"`) {
+		t.Fatalf("expected id to be prefixed, got %q", output)
+	}
+}
+
+func TestSanitizeRejectsInvalidID(t *testing.T) {
+	input := `<h2 id="2-starts-with-digit">Title</h2>`
+	output := Sanitize("https://example.org/", input)
+	if strings.Contains(output, "id=") {
+		t.Fatalf("expected invalid id to be dropped, got %q", output)
+	}
+}
+
+func TestSanitizeKeepsInPageAnchorLinksInSyncWithPrefixedIDs(t *testing.T) {
+	input := `<p><a href="#section-2">Jump to section 2</a></p><h2 id="section-2">Section 2</h2>`
+	output := Sanitize("https://example.org/", input)
+
+	if !strings.Contains(output, `href="#yarr-section-2"`) {
+		t.Fatalf("expected anchor href to be prefixed to match the heading id, got %q", output)
+	}
+	if !strings.Contains(output, `id="yarr-section-2"`) {
+		t.Fatalf("expected heading id to be prefixed, got %q", output)
+	}
+}
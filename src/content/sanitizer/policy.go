@@ -0,0 +1,166 @@
+package sanitizer
+
+import (
+	"strings"
+	"sync"
+)
+
+// Policy holds the runtime-configurable allow/deny lists used by the
+// sanitizer. It lets a deployment add extra iframe sources, resource
+// blocks, tag attributes or video embeds without recompiling.
+type Policy struct {
+	// IframeDomains lists hosts (in addition to the page's own origin)
+	// that iframes are allowed to embed from.
+	IframeDomains []string
+	// BlockedResources is a list of substrings; any href/src containing
+	// one of them is dropped.
+	BlockedResources []string
+	// ExtraTags maps a tag to extra allowed attribute names. An existing
+	// tag's attributes are extended, not replaced. It does not allow new
+	// tags: a tag the built-in allowlist rejects stays rejected. Only
+	// names in extraAttributeVocabulary are kept when merged.
+	ExtraTags map[string][]string
+	// VideoIframeDomains lists hosts whose iframes get wrapped in a
+	// "video-wrapper" div.
+	VideoIframeDomains []string
+	// RewriteYoutubeEmbeds controls whether youtube.com embed URLs are
+	// rewritten to the privacy-preserving youtube-nocookie.com domain.
+	// Nil means "use the default" (on); set to a non-nil value to override.
+	RewriteYoutubeEmbeds *bool
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// extraAttributeVocabulary is the fixed set of attribute names ExtraTags
+// is ever allowed to grant. It is deliberately small and excludes
+// anything that can execute script or load markup (event handlers,
+// "style", "srcdoc", "formaction", ...): a policy submitted at runtime
+// can only ever add cosmetic/structural attributes to an already-allowed
+// tag, never reopen the XSS holes the sanitizer exists to close.
+var extraAttributeVocabulary = map[string]bool{
+	"id":    true,
+	"class": true,
+	"lang":  true,
+	"dir":   true,
+	"title": true,
+}
+
+// sanitizeExtraTagAttrs drops any attribute name outside
+// extraAttributeVocabulary from a submitted ExtraTags map.
+func sanitizeExtraTagAttrs(tagAttrs map[string][]string) map[string][]string {
+	sanitized := make(map[string][]string, len(tagAttrs))
+	for tag, attrs := range tagAttrs {
+		var allowed []string
+		for _, attr := range attrs {
+			if extraAttributeVocabulary[strings.ToLower(attr)] {
+				allowed = append(allowed, attr)
+			}
+		}
+		if len(allowed) > 0 {
+			sanitized[tag] = allowed
+		}
+	}
+	return sanitized
+}
+
+// DefaultPolicy returns the built-in lists the sanitizer has always used.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		IframeDomains: []string{
+			"bandcamp.com",
+			"cdn.embedly.com",
+			"invidio.us",
+			"player.bilibili.com",
+			"player.vimeo.com",
+			"soundcloud.com",
+			"vk.com",
+			"w.soundcloud.com",
+			"www.dailymotion.com",
+			"www.youtube-nocookie.com",
+			"www.youtube.com",
+		},
+		BlockedResources: []string{
+			"feedsportal.com",
+			"api.flattr.com",
+			"stats.wordpress.com",
+			"plus.google.com/share",
+			"twitter.com/share",
+			"feeds.feedburner.com",
+		},
+		ExtraTags: map[string][]string{},
+		VideoIframeDomains: []string{
+			"player.bilibili.com",
+			"player.vimeo.com",
+			"www.dailymotion.com",
+			"www.youtube-nocookie.com",
+			"www.youtube.com",
+		},
+		RewriteYoutubeEmbeds: boolPtr(true),
+	}
+}
+
+// MergePolicy returns a new Policy combining the built-in defaults with the
+// extra entries from p. Lists are unioned (deduplicated), and ExtraTags
+// attributes are appended to any existing tag's attribute list rather than
+// replacing it. A nil p returns the defaults unchanged.
+func MergePolicy(p *Policy) *Policy {
+	merged := DefaultPolicy()
+	if p == nil {
+		return merged
+	}
+
+	merged.IframeDomains = unionStrings(merged.IframeDomains, p.IframeDomains)
+	merged.BlockedResources = unionStrings(merged.BlockedResources, p.BlockedResources)
+	merged.VideoIframeDomains = unionStrings(merged.VideoIframeDomains, p.VideoIframeDomains)
+
+	for tag, attrs := range sanitizeExtraTagAttrs(p.ExtraTags) {
+		merged.ExtraTags[tag] = unionStrings(merged.ExtraTags[tag], attrs)
+	}
+
+	if p.RewriteYoutubeEmbeds != nil {
+		merged.RewriteYoutubeEmbeds = p.RewriteYoutubeEmbeds
+	}
+
+	return merged
+}
+
+func unionStrings(base, extra []string) []string {
+	seen := make(map[string]bool, len(base))
+	result := make([]string, 0, len(base)+len(extra))
+	for _, s := range base {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	for _, s := range extra {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+var (
+	policyMu     sync.RWMutex
+	activePolicy = DefaultPolicy()
+)
+
+// SetPolicy replaces the sanitizer's active policy, merging it with the
+// built-in defaults. Passing nil restores the defaults. This is exposed so
+// server.Server can apply a user-configured policy loaded from settings.
+func SetPolicy(p *Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	activePolicy = MergePolicy(p)
+}
+
+// CurrentPolicy returns the sanitizer's active policy.
+func CurrentPolicy() *Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return activePolicy
+}
@@ -0,0 +1,93 @@
+package sanitizer
+
+import "testing"
+
+func TestMergePolicyNilReturnsDefaults(t *testing.T) {
+	merged := MergePolicy(nil)
+	def := DefaultPolicy()
+
+	if len(merged.IframeDomains) != len(def.IframeDomains) {
+		t.Fatalf("expected %d iframe domains, got %d", len(def.IframeDomains), len(merged.IframeDomains))
+	}
+}
+
+func TestMergePolicyUnionsLists(t *testing.T) {
+	merged := MergePolicy(&Policy{
+		IframeDomains:      []string{"odysee.com", "bandcamp.com"},
+		BlockedResources:   []string{"tracker.example.com"},
+		VideoIframeDomains: []string{"odysee.com"},
+	})
+
+	if !inList("odysee.com", merged.IframeDomains) {
+		t.Fatal("expected odysee.com to be added to iframe domains")
+	}
+	if !inList("bandcamp.com", merged.IframeDomains) {
+		t.Fatal("expected default bandcamp.com to still be present")
+	}
+
+	count := 0
+	for _, domain := range merged.IframeDomains {
+		if domain == "bandcamp.com" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected bandcamp.com to appear once, got %d", count)
+	}
+
+	if !inList("tracker.example.com", merged.BlockedResources) {
+		t.Fatal("expected extra blocked resource to be present")
+	}
+	if !inList("odysee.com", merged.VideoIframeDomains) {
+		t.Fatal("expected extra video iframe domain to be present")
+	}
+}
+
+func TestMergePolicyExtendsTagAttributes(t *testing.T) {
+	merged := MergePolicy(&Policy{
+		ExtraTags: map[string][]string{
+			"a": {"id"},
+		},
+	})
+
+	if !inList("id", merged.ExtraTags["a"]) {
+		t.Fatal("expected extra tag attribute to be present")
+	}
+}
+
+func TestMergePolicyRejectsDangerousExtraAttrs(t *testing.T) {
+	merged := MergePolicy(&Policy{
+		ExtraTags: map[string][]string{
+			"a":   {"onclick", "id"},
+			"img": {"onerror"},
+			"div": {"style", "srcdoc"},
+		},
+	})
+
+	if inList("onclick", merged.ExtraTags["a"]) {
+		t.Fatal("expected onclick to be rejected")
+	}
+	if !inList("id", merged.ExtraTags["a"]) {
+		t.Fatal("expected id to still be allowed")
+	}
+	if _, ok := merged.ExtraTags["img"]; ok {
+		t.Fatal("expected img to be dropped entirely once onerror is filtered out")
+	}
+	if _, ok := merged.ExtraTags["div"]; ok {
+		t.Fatal("expected div to be dropped entirely once style/srcdoc are filtered out")
+	}
+}
+
+func TestSetPolicyAndCurrentPolicy(t *testing.T) {
+	defer SetPolicy(nil)
+
+	SetPolicy(&Policy{IframeDomains: []string{"music.youtube.com"}})
+	if !inList("music.youtube.com", CurrentPolicy().IframeDomains) {
+		t.Fatal("expected custom iframe domain to be active")
+	}
+
+	SetPolicy(nil)
+	if inList("music.youtube.com", CurrentPolicy().IframeDomains) {
+		t.Fatal("expected policy reset to defaults")
+	}
+}
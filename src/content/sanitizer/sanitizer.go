@@ -17,6 +17,13 @@ import (
 )
 
 var splitSrcsetRegex = regexp.MustCompile(`,\s+`)
+var youtubeEmbedRegex = regexp.MustCompile(`^(https?:)?//(?:www\.)?youtube\.com/embed/(.+)$`)
+var idAttrRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_:.-]*$`)
+
+// idAttributePrefix is prepended to sanitized "id" attributes so that
+// feed-supplied anchors (used for deep-linking and the article TOC) can
+// never collide with yarr's own CSS/JS ids.
+const idAttributePrefix = "yarr-"
 
 // Sanitize returns safe HTML.
 func Sanitize(baseURL, input string) string {
@@ -55,6 +62,10 @@ func Sanitize(baseURL, input string) string {
 			parentTag = tagName
 
 			if isValidTag(tagName) {
+				if isPixelTracker(tagName, token.Attr) {
+					continue
+				}
+
 				attrNames, htmlAttributes := sanitizeAttributes(baseURL, tagName, token.Attr)
 
 				if hasRequiredAttributes(tagName, attrNames) {
@@ -96,6 +107,10 @@ func Sanitize(baseURL, input string) string {
 		case html.SelfClosingTagToken:
 			tagName := token.Data
 			if isValidTag(tagName) {
+				if isPixelTracker(tagName, token.Attr) {
+					continue
+				}
+
 				attrNames, htmlAttributes := sanitizeAttributes(baseURL, tagName, token.Attr)
 
 				if hasRequiredAttributes(tagName, attrNames) {
@@ -124,10 +139,30 @@ func sanitizeAttributes(baseURL, tagName string, attributes []html.Attribute) ([
 			value = sanitizeSrcsetAttr(baseURL, value)
 		}
 
+		if attribute.Key == "id" {
+			if !isValidIDAttribute(value) {
+				continue
+			}
+			value = idAttributePrefix + value
+		}
+
+		// An in-page anchor link (e.g. from a server-generated table of
+		// contents) must be prefixed the same way as the "id" attributes
+		// above, or it will never match the heading it points to.
+		if tagName == "a" && attribute.Key == "href" && strings.HasPrefix(value, "#") {
+			fragment := value[1:]
+			if !isValidIDAttribute(fragment) {
+				continue
+			}
+			attrNames = append(attrNames, attribute.Key)
+			htmlAttrs = append(htmlAttrs, fmt.Sprintf(`%s="#%s"`, attribute.Key, html.EscapeString(idAttributePrefix+fragment)))
+			continue
+		}
+
 		if isExternalResourceAttribute(attribute.Key) {
 			if tagName == "iframe" {
 				if isValidIframeSource(baseURL, attribute.Val) {
-					value = attribute.Val
+					value = rewriteYoutubeEmbed(attribute.Val)
 				} else {
 					continue
 				}
@@ -180,11 +215,17 @@ func isValidTag(tagName string) bool {
 }
 
 func isValidAttribute(tagName, attributeName string) bool {
-	if attrs, ok := allowedAttrs[tagName]; ok {
-		return attrs.has(attributeName)
+	if attrs, ok := allowedAttrs[tagName]; ok && attrs.has(attributeName) {
+		return true
 	}
-	if allowedSvgTags.has(tagName) {
-		return allowedSvgAttrs.has(attributeName)
+	if allowedSvgTags.has(tagName) && allowedSvgAttrs.has(attributeName) {
+		return true
+	}
+	// ExtraTags is re-read on every call (via CurrentPolicy) so a policy
+	// applied at runtime through SetPolicy/the settings endpoint takes
+	// effect immediately, without needing the process to restart.
+	if extra, ok := CurrentPolicy().ExtraTags[tagName]; ok {
+		return inList(attributeName, extra)
 	}
 	return false
 }
@@ -228,18 +269,68 @@ func hasValidURIScheme(src string) bool {
 	return allowedURISchemes.has(scheme)
 }
 
+// isValidIDAttribute reports whether value is safe to use as an HTML id,
+// following the same rule miniflux applies: it must start with a letter
+// and contain only letters, digits, underscores, colons, dots or hyphens.
+func isValidIDAttribute(value string) bool {
+	return idAttrRegex.MatchString(value)
+}
+
 func isBlockedResource(src string) bool {
+	for _, element := range CurrentPolicy().BlockedResources {
+		if strings.Contains(src, element) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPixelTracker reports whether the given tag is a 1x1 (or 0x0) <img>
+// read-receipt beacon, either by its declared dimensions or by its src
+// pointing at a known tracker host.
+func isPixelTracker(tagName string, attributes []html.Attribute) bool {
+	if tagName != "img" {
+		return false
+	}
+
+	var width, height, src string
+	for _, attribute := range attributes {
+		switch attribute.Key {
+		case "width":
+			width = attribute.Val
+		case "height":
+			height = attribute.Val
+		case "src":
+			src = attribute.Val
+		}
+	}
+
+	if (width == "1" || width == "0") && (height == "1" || height == "0") {
+		return true
+	}
+
+	return isTrackerHost(src)
+}
+
+func isTrackerHost(src string) bool {
 	blacklist := []string{
 		"feedsportal.com",
-		"api.flattr.com",
+		"feedburner.com",
+		"feedblitz.com",
+		"doubleclick.net",
+		"google-analytics.com",
+		"googlesyndication.com",
 		"stats.wordpress.com",
-		"plus.google.com/share",
-		"twitter.com/share",
-		"feeds.feedburner.com",
+	}
+
+	domain := htmlutil.URLDomain(src)
+	if domain == "" {
+		return false
 	}
 
 	for _, element := range blacklist {
-		if strings.Contains(src, element) {
+		if strings.Contains(domain, element) {
 			return true
 		}
 	}
@@ -247,28 +338,28 @@ func isBlockedResource(src string) bool {
 	return false
 }
 
-func isValidIframeSource(baseURL, src string) bool {
-	whitelist := []string{
-		"bandcamp.com",
-		"cdn.embedly.com",
-		"invidio.us",
-		"player.bilibili.com",
-		"player.vimeo.com",
-		"soundcloud.com",
-		"vk.com",
-		"w.soundcloud.com",
-		"www.dailymotion.com",
-		"www.youtube-nocookie.com",
-		"www.youtube.com",
+// rewriteYoutubeEmbed rewrites a youtube.com/embed/... URL to the
+// youtube-nocookie.com equivalent, preserving the video id and any query
+// parameters. Other URLs are returned unchanged.
+func rewriteYoutubeEmbed(src string) string {
+	policy := CurrentPolicy()
+	if policy.RewriteYoutubeEmbeds != nil && !*policy.RewriteYoutubeEmbeds {
+		return src
+	}
+	if youtubeEmbedRegex.MatchString(src) {
+		return youtubeEmbedRegex.ReplaceAllString(src, "https://www.youtube-nocookie.com/embed/$2")
 	}
+	return src
+}
 
+func isValidIframeSource(baseURL, src string) bool {
 	domain := htmlutil.URLDomain(src)
 	// allow iframe from same origin
 	if htmlutil.URLDomain(baseURL) == domain {
 		return true
 	}
 
-	for _, safeDomain := range whitelist {
+	for _, safeDomain := range CurrentPolicy().IframeDomains {
 		if safeDomain == domain {
 			return true
 		}
@@ -284,9 +375,9 @@ func getTagAllowList() map[string][]string {
 	whitelist["audio"] = []string{"src"}
 	whitelist["video"] = []string{"poster", "height", "width", "src"}
 	whitelist["source"] = []string{"src", "type", "srcset", "sizes", "media"}
-	whitelist["dt"] = []string{}
-	whitelist["dd"] = []string{}
-	whitelist["dl"] = []string{}
+	whitelist["dt"] = []string{"id"}
+	whitelist["dd"] = []string{"id"}
+	whitelist["dl"] = []string{"id"}
 	whitelist["table"] = []string{}
 	whitelist["caption"] = []string{}
 	whitelist["thead"] = []string{}
@@ -294,12 +385,12 @@ func getTagAllowList() map[string][]string {
 	whitelist["tr"] = []string{}
 	whitelist["td"] = []string{"rowspan", "colspan"}
 	whitelist["th"] = []string{"rowspan", "colspan"}
-	whitelist["h1"] = []string{}
-	whitelist["h2"] = []string{}
-	whitelist["h3"] = []string{}
-	whitelist["h4"] = []string{}
-	whitelist["h5"] = []string{}
-	whitelist["h6"] = []string{}
+	whitelist["h1"] = []string{"id"}
+	whitelist["h2"] = []string{"id"}
+	whitelist["h3"] = []string{"id"}
+	whitelist["h4"] = []string{"id"}
+	whitelist["h5"] = []string{"id"}
+	whitelist["h6"] = []string{"id"}
 	whitelist["strong"] = []string{}
 	whitelist["em"] = []string{}
 	whitelist["code"] = []string{}
@@ -307,12 +398,12 @@ func getTagAllowList() map[string][]string {
 	whitelist["blockquote"] = []string{}
 	whitelist["q"] = []string{"cite"}
 	whitelist["p"] = []string{}
-	whitelist["ul"] = []string{}
-	whitelist["li"] = []string{}
-	whitelist["ol"] = []string{}
+	whitelist["ul"] = []string{"id"}
+	whitelist["li"] = []string{"id"}
+	whitelist["ol"] = []string{"id"}
 	whitelist["br"] = []string{}
 	whitelist["del"] = []string{}
-	whitelist["a"] = []string{"href", "title"}
+	whitelist["a"] = []string{"href", "title", "id"}
 	whitelist["figure"] = []string{}
 	whitelist["figcaption"] = []string{}
 	whitelist["cite"] = []string{}
@@ -322,7 +413,7 @@ func getTagAllowList() map[string][]string {
 	whitelist["wbr"] = []string{}
 	whitelist["dfn"] = []string{}
 	whitelist["sub"] = []string{}
-	whitelist["sup"] = []string{}
+	whitelist["sup"] = []string{"id"}
 	whitelist["var"] = []string{}
 	whitelist["samp"] = []string{}
 	whitelist["s"] = []string{}
@@ -388,6 +479,7 @@ func sanitizeSrcsetAttr(baseURL, value string) string {
 				if sanitizedSource == "" {
 					continue
 				}
+				sanitizedSource = rewriteYoutubeEmbed(sanitizedSource)
 			}
 
 			if nbParts == 2 && isValidWidthOrDensityDescriptor(parts[1]) {
@@ -436,18 +528,11 @@ func isValidDataAttribute(value string) bool {
 }
 
 func isVideoIframe(token html.Token) bool {
-	videoWhitelist := map[string]bool{
-		"player.bilibili.com":      true,
-		"player.vimeo.com":         true,
-		"www.dailymotion.com":      true,
-		"www.youtube-nocookie.com": true,
-		"www.youtube.com":          true,
-	}
 	if token.Data == "iframe" {
 		for _, attr := range token.Attr {
 			if attr.Key == "src" {
 				domain := htmlutil.URLDomain(attr.Val)
-				return videoWhitelist[domain]
+				return inList(domain, CurrentPolicy().VideoIframeDomains)
 			}
 		}
 	}
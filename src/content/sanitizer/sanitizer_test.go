@@ -0,0 +1,65 @@
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeDropsPixelTrackerByDimensions(t *testing.T) {
+	for _, dims := range []struct{ width, height string }{
+		{"1", "1"},
+		{"0", "0"},
+		{"1", "0"},
+	} {
+		input := `<p>hello <img src="https://example.org/x.png" width="` + dims.width + `" height="` + dims.height + `"> world</p>`
+		output := Sanitize("https://example.org/", input)
+		if strings.Contains(output, "<img") {
+			t.Fatalf("expected %sx%s img to be dropped, got %q", dims.width, dims.height, output)
+		}
+	}
+}
+
+func TestSanitizeDropsKnownTrackerHosts(t *testing.T) {
+	for _, host := range []string{
+		"feeds.feedburner.com",
+		"stats.wordpress.com",
+		"www.google-analytics.com",
+		"www.doubleclick.net",
+	} {
+		input := `<img src="https://` + host + `/beacon.gif">`
+		output := Sanitize("https://example.org/", input)
+		if strings.Contains(output, "<img") {
+			t.Fatalf("expected tracker host %s to be dropped, got %q", host, output)
+		}
+	}
+}
+
+func TestSanitizeKeepsLegitImage(t *testing.T) {
+	input := `<img src="https://example.org/photo.jpg" width="600" height="400" alt="a photo">`
+	output := Sanitize("https://example.org/", input)
+	if !strings.Contains(output, "<img") {
+		t.Fatalf("expected legit image to be kept, got %q", output)
+	}
+}
+
+func TestSanitizeAppliesExtraTagAttributeFromPolicy(t *testing.T) {
+	defer SetPolicy(nil)
+	SetPolicy(&Policy{ExtraTags: map[string][]string{"p": {"lang"}}})
+
+	input := `<p lang="fr">Bonjour</p>`
+	output := Sanitize("https://example.org/", input)
+	if !strings.Contains(output, `lang="fr"`) {
+		t.Fatalf("expected policy-granted attribute to survive sanitization, got %q", output)
+	}
+}
+
+func TestSanitizeNeverAppliesDangerousExtraAttributeFromPolicy(t *testing.T) {
+	defer SetPolicy(nil)
+	SetPolicy(&Policy{ExtraTags: map[string][]string{"p": {"onclick"}}})
+
+	input := `<p onclick="alert(1)">hi</p>`
+	output := Sanitize("https://example.org/", input)
+	if strings.Contains(output, "onclick") {
+		t.Fatalf("expected onclick to never be grantable via policy, got %q", output)
+	}
+}
@@ -0,0 +1,25 @@
+package sanitizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeRewritesYoutubeEmbed(t *testing.T) {
+	input := `<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ?start=30" width="560" height="315"></iframe>`
+	output := Sanitize("https://example.org/", input)
+	if !strings.Contains(output, `src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?start=30"`) {
+		t.Fatalf("expected youtube embed to be rewritten with query preserved, got %q", output)
+	}
+}
+
+func TestSanitizeKeepsYoutubeEmbedWhenRewriteDisabled(t *testing.T) {
+	defer SetPolicy(nil)
+	SetPolicy(&Policy{RewriteYoutubeEmbeds: boolPtr(false)})
+
+	input := `<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ" width="560" height="315"></iframe>`
+	output := Sanitize("https://example.org/", input)
+	if !strings.Contains(output, `src="https://www.youtube.com/embed/dQw4w9WgXcQ"`) {
+		t.Fatalf("expected youtube embed to be left untouched, got %q", output)
+	}
+}
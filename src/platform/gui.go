@@ -3,15 +3,24 @@
 package platform
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/gen2brain/beeep"
 	"github.com/nkanaev/yarr/src/server"
 	"github.com/nkanaev/yarr/src/systray"
 )
 
+// unreadPollInterval controls how often the tray icon's tooltip is
+// refreshed with the current unread count.
+const unreadPollInterval = 30 * time.Second
+
 func Start(s *server.Server) {
 	systrayOnReady := func() {
 		systray.SetIcon(Icon)
 
 		menuOpen := systray.AddMenuItem("Open", "")
+		menuMarkAllRead := systray.AddMenuItem("Mark all read", "")
 		systray.AddSeparator()
 		menuQuit := systray.AddMenuItem("Quit", "")
 
@@ -20,13 +29,58 @@ func Start(s *server.Server) {
 				select {
 				case <-menuOpen.ClickedCh:
 					Open(s.GetAddr())
+				case <-menuMarkAllRead.ClickedCh:
+					s.MarkAllRead()
 				case <-menuQuit.ClickedCh:
 					systray.Quit()
 				}
 			}
 		}()
 
+		go pollUnreadCount(s)
+		go notifyNewItems(s)
+
 		s.Start()
 	}
 	systray.Run(systrayOnReady, nil)
 }
+
+// pollUnreadCount periodically refreshes the tray tooltip/title with the
+// total number of unread items.
+func pollUnreadCount(s *server.Server) {
+	ticker := time.NewTicker(unreadPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count := s.UnreadCount()
+		title := "yarr"
+		if count > 0 {
+			title = fmt.Sprintf("yarr — %d unread", count)
+		}
+		systray.SetTooltip(title)
+		systray.SetTitle(title)
+	}
+}
+
+// notifyNewItems listens for newly fetched items reported by the sync
+// subsystem and surfaces a native OS notification per feed.
+//
+// beeep.Notify has no click-callback parameter, so this does not open
+// the reader when the notification itself is clicked — that would need
+// platform-native toast code (e.g. a Windows COM activation handler or a
+// macOS NSUserNotification delegate) in place of beeep, which is out of
+// scope here. As a stand-in, the reader address is included in the
+// notification body so the destination is at least visible; the tray's
+// existing "Open" menu item remains the reliable way to get there.
+func notifyNewItems(s *server.Server) {
+	for event := range s.Events() {
+		if event.NewItems == 0 {
+			continue
+		}
+		beeep.Notify(
+			event.FeedTitle,
+			fmt.Sprintf("%d new item(s) — %s", event.NewItems, s.GetAddr()),
+			"",
+		)
+	}
+}
@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/nkanaev/yarr/src/assets"
 	"github.com/nkanaev/yarr/src/server/router"
@@ -13,6 +16,29 @@ type Middleware struct {
 	Password string
 	BasePath string
 	Public   string
+
+	// Throttler rate-limits login attempts. Created lazily with
+	// NewThrottler if left nil, so existing callers don't need to set it.
+	Throttler *Throttler
+
+	throttlerOnce sync.Once
+}
+
+func (m *Middleware) throttle() *Throttler {
+	m.throttlerOnce.Do(func() {
+		if m.Throttler == nil {
+			m.Throttler = NewThrottler()
+		}
+	})
+	return m.Throttler
+}
+
+func throttleKey(r *http.Request, username string) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host + "|" + username
 }
 
 func unsafeMethod(method string) bool {
@@ -39,11 +65,28 @@ func (m *Middleware) Handler(c *router.Context) {
 	if c.Req.Method == "POST" {
 		username := c.Req.FormValue("username")
 		password := c.Req.FormValue("password")
-		if StringsEqual(username, m.Username) && StringsEqual(password, m.Password) {
+		key := throttleKey(c.Req, username)
+
+		if wait, locked := m.throttle().Locked(key); locked {
+			c.Out.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			c.HTML(http.StatusTooManyRequests, assets.Template("login.html"), map[string]string{
+				"username": username,
+				"error":    "Too many failed attempts. Try again later.",
+			})
+			return
+		}
+
+		// Both comparisons always run so that a nonexistent username does
+		// not resolve faster than a wrong password, which would otherwise
+		// leak whether the username exists via response timing.
+		validUsername := StringsEqual(username, m.Username)
+		validPassword := StringsEqual(password, m.Password)
+		if validUsername && validPassword {
 			Authenticate(c.Out, m.Username, m.Password, m.BasePath)
 			c.Redirect(rootUrl)
 			return
 		} else {
+			m.throttle().RecordFailure(key)
 			c.HTML(http.StatusOK, assets.Template("login.html"), map[string]string{
 				"username": username,
 				"error":    "Invalid username/password",
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// ThrottleStore records failed login attempts and lockouts keyed by an
+// arbitrary string (typically remote IP + attempted username). It is an
+// interface so the in-memory default can be swapped for something shared
+// across processes (e.g. redis) in larger deployments.
+type ThrottleStore interface {
+	// RecordFailure registers a failed attempt for key at time now.
+	RecordFailure(key string, now time.Time)
+	// Failures returns the number of failed attempts for key recorded
+	// since the given time.
+	Failures(key string, since time.Time) int
+	// Lock locks key until the given time.
+	Lock(key string, until time.Time)
+	// LockedUntil returns the time key is locked until, if it is
+	// currently locked.
+	LockedUntil(key string, now time.Time) (time.Time, bool)
+}
+
+// maxThrottledKeys bounds how many distinct keys memoryThrottleStore will
+// track. An attacker who varies the remote IP or username on every
+// attempt would otherwise grow the store without bound; once the cap is
+// reached, the oldest tracked key is evicted to make room for the new
+// one.
+const maxThrottledKeys = 10000
+
+type memoryThrottleStore struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	lockouts map[string]time.Time
+	// order records insertion order of failures, oldest first, so the
+	// oldest key can be evicted once maxThrottledKeys is exceeded.
+	order []string
+}
+
+func newMemoryThrottleStore() *memoryThrottleStore {
+	return &memoryThrottleStore{
+		failures: make(map[string][]time.Time),
+		lockouts: make(map[string]time.Time),
+	}
+}
+
+func (s *memoryThrottleStore) RecordFailure(key string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, tracked := s.failures[key]; !tracked {
+		s.order = append(s.order, key)
+		for len(s.order) > maxThrottledKeys {
+			oldest := s.order[0]
+			s.order = s.order[1:]
+			delete(s.failures, oldest)
+			delete(s.lockouts, oldest)
+		}
+	}
+	s.failures[key] = append(s.failures[key], now)
+}
+
+func (s *memoryThrottleStore) Failures(key string, since time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.failures[key]
+	count := 0
+	kept := attempts[:0]
+	for _, t := range attempts {
+		if t.After(since) {
+			kept = append(kept, t)
+			count++
+		}
+	}
+	s.failures[key] = kept
+	return count
+}
+
+func (s *memoryThrottleStore) Lock(key string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lockouts[key] = until
+}
+
+func (s *memoryThrottleStore) LockedUntil(key string, now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.lockouts[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	if now.After(until) {
+		delete(s.lockouts, key)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// Throttler rate-limits login attempts: after MaxAttempts failures within
+// Window for a given key, further attempts are locked out for
+// LockoutDuration.
+type Throttler struct {
+	Store           ThrottleStore
+	Window          time.Duration
+	MaxAttempts     int
+	LockoutDuration time.Duration
+
+	// Now returns the current time. Overridable in tests with a fake clock.
+	Now func() time.Time
+}
+
+const (
+	defaultThrottleWindow      = time.Minute
+	defaultThrottleMaxAttempts = 5
+	defaultLockoutDuration     = 5 * time.Minute
+)
+
+// NewThrottler builds a Throttler with the default window, attempt budget
+// and lockout duration, backed by an in-memory store.
+func NewThrottler() *Throttler {
+	return &Throttler{
+		Store:           newMemoryThrottleStore(),
+		Window:          defaultThrottleWindow,
+		MaxAttempts:     defaultThrottleMaxAttempts,
+		LockoutDuration: defaultLockoutDuration,
+		Now:             time.Now,
+	}
+}
+
+func (t *Throttler) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// Locked reports whether key is currently locked out, and if so for how
+// much longer.
+func (t *Throttler) Locked(key string) (time.Duration, bool) {
+	until, locked := t.Store.LockedUntil(key, t.now())
+	if !locked {
+		return 0, false
+	}
+	return until.Sub(t.now()), true
+}
+
+// RecordFailure registers a failed login attempt for key. Once the number
+// of failures within Window reaches MaxAttempts, key is locked out for
+// LockoutDuration.
+func (t *Throttler) RecordFailure(key string) {
+	now := t.now()
+	t.Store.RecordFailure(key, now)
+
+	if t.Store.Failures(key, now.Add(-t.Window)) >= t.MaxAttempts {
+		t.Store.Lock(key, now.Add(t.LockoutDuration))
+	}
+}
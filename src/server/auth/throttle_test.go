@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newThrottlerWithClock(clock *fakeClock) *Throttler {
+	return &Throttler{
+		Store:           newMemoryThrottleStore(),
+		Window:          time.Minute,
+		MaxAttempts:     3,
+		LockoutDuration: 5 * time.Minute,
+		Now:             func() time.Time { return clock.now },
+	}
+}
+
+func TestThrottlerLocksOutAfterMaxAttempts(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	throttler := newThrottlerWithClock(clock)
+	key := "1.2.3.4|admin"
+
+	for i := 0; i < 2; i++ {
+		throttler.RecordFailure(key)
+		if _, locked := throttler.Locked(key); locked {
+			t.Fatalf("should not be locked after %d failures", i+1)
+		}
+	}
+
+	throttler.RecordFailure(key)
+	wait, locked := throttler.Locked(key)
+	if !locked {
+		t.Fatal("expected lockout after reaching MaxAttempts")
+	}
+	if wait <= 0 {
+		t.Fatalf("expected positive wait duration, got %v", wait)
+	}
+}
+
+func TestThrottlerLockoutExpires(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	throttler := newThrottlerWithClock(clock)
+	key := "1.2.3.4|admin"
+
+	for i := 0; i < 3; i++ {
+		throttler.RecordFailure(key)
+	}
+	if _, locked := throttler.Locked(key); !locked {
+		t.Fatal("expected lockout")
+	}
+
+	clock.advance(throttler.LockoutDuration + time.Second)
+	if _, locked := throttler.Locked(key); locked {
+		t.Fatal("expected lockout to have expired")
+	}
+}
+
+func TestThrottlerWindowSlides(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	throttler := newThrottlerWithClock(clock)
+	key := "1.2.3.4|admin"
+
+	throttler.RecordFailure(key)
+	clock.advance(throttler.Window + time.Second)
+	throttler.RecordFailure(key)
+	throttler.RecordFailure(key)
+
+	if _, locked := throttler.Locked(key); locked {
+		t.Fatal("failures outside the window should not count towards lockout")
+	}
+}
+
+func TestMemoryThrottleStoreEvictsOldestKeyPastCap(t *testing.T) {
+	store := newMemoryThrottleStore()
+	now := time.Now()
+
+	for i := 0; i < maxThrottledKeys; i++ {
+		store.RecordFailure(string(rune(i)), now)
+	}
+	if len(store.failures) != maxThrottledKeys {
+		t.Fatalf("expected %d tracked keys, got %d", maxThrottledKeys, len(store.failures))
+	}
+
+	store.RecordFailure("one-more", now)
+	if len(store.failures) != maxThrottledKeys {
+		t.Fatalf("expected tracked keys to stay capped at %d, got %d", maxThrottledKeys, len(store.failures))
+	}
+	if _, stillTracked := store.failures[string(rune(0))]; stillTracked {
+		t.Fatal("expected the oldest key to be evicted once the cap was exceeded")
+	}
+	if _, tracked := store.failures["one-more"]; !tracked {
+		t.Fatal("expected the newest key to be tracked")
+	}
+}
+
+func TestThrottlerKeysAreIndependent(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	throttler := newThrottlerWithClock(clock)
+
+	for i := 0; i < 3; i++ {
+		throttler.RecordFailure("1.2.3.4|admin")
+	}
+	if _, locked := throttler.Locked("5.6.7.8|admin"); locked {
+		t.Fatal("a different remote IP should not be locked out")
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/nkanaev/yarr/src/content/sanitizer"
+)
+
+// Server is the surface the HTTP handlers, the settings API and the
+// platform/systray package all drive. The feed storage and fetching live
+// in their own packages, and the sync loop is in sync.go; this file covers the unread
+// counter, the mark-all-read action and the new-item event feed that
+// platform.Start needs.
+type Server struct {
+	Addr string
+
+	mu     sync.RWMutex
+	unread int
+
+	events chan NewItemsEvent
+
+	settingsStore SettingsStore
+}
+
+// NewItemsEvent is published on Server.Events() whenever the sync loop
+// finishes a feed fetch that brought in items not already known.
+type NewItemsEvent struct {
+	FeedTitle string
+	NewItems  int
+}
+
+// eventBacklog bounds how many pending NewItemsEvent values Events() will
+// buffer before the sync loop starts dropping them rather than blocking.
+const eventBacklog = 16
+
+// NewServer builds a Server for addr; it does not open a listener.
+// settingsStore holds user-configurable settings (currently the
+// sanitizer policy); passing nil falls back to an in-memory store, which
+// does not survive a restart. A policy already in the store is applied
+// to the sanitizer here; otherwise the built-in lists stay in effect.
+func NewServer(addr string, settingsStore SettingsStore) *Server {
+	if settingsStore == nil {
+		settingsStore = newMemorySettingsStore()
+	}
+	if policy, ok := settingsStore.LoadSanitizerPolicy(); ok {
+		sanitizer.SetPolicy(policy)
+	}
+
+	return &Server{
+		Addr:          addr,
+		events:        make(chan NewItemsEvent, eventBacklog),
+		settingsStore: settingsStore,
+	}
+}
+
+func (s *Server) GetAddr() string {
+	return s.Addr
+}
+
+// Start is the hook platform.Start calls once the tray is ready. It has
+// no body: it starts no HTTP listener and returns immediately.
+func (s *Server) Start() {}
+
+// UnreadCount returns the unread counter: the number of new items the
+// sync loop has reported since the server was built or since the last
+// MarkAllRead.
+func (s *Server) UnreadCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unread
+}
+
+// MarkAllRead resets the unread counter to zero. The Server holds no
+// items itself, so no stored item is changed.
+func (s *Server) MarkAllRead() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unread = 0
+}
+
+// Events returns the channel the sync loop publishes NewItemsEvent values
+// on. Consumers (the systray notifier) should range over it for as long
+// as the server runs.
+func (s *Server) Events() <-chan NewItemsEvent {
+	return s.events
+}
+
+// reportNewItems is called by the sync loop once a feed fetch completes
+// with items that weren't already in storage. It updates the unread
+// counter and publishes a NewItemsEvent, dropping the event (never
+// blocking the sync loop) if no one is listening fast enough.
+func (s *Server) reportNewItems(feedTitle string, count int) {
+	if count <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.unread += count
+	s.mu.Unlock()
+
+	select {
+	case s.events <- NewItemsEvent{FeedTitle: feedTitle, NewItems: count}:
+	default:
+	}
+}
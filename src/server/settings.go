@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/nkanaev/yarr/src/content/sanitizer"
+	"github.com/nkanaev/yarr/src/server/router"
+)
+
+// sanitizerPolicyRequest is the JSON body accepted by the sanitizer
+// settings endpoint. It carries sanitizer.Policy's list fields so a
+// deployer can add extra iframe sources, resource blocks or tag
+// attributes (e.g. music.youtube.com, odysee.com, a self-hosted PeerTube
+// instance) from the settings UI without recompiling. RewriteYoutubeEmbeds
+// is not settable here, so a saved policy leaves it at its default (on).
+type sanitizerPolicyRequest struct {
+	IframeDomains      []string            `json:"iframe_domains"`
+	BlockedResources   []string            `json:"blocked_resources"`
+	ExtraTags          map[string][]string `json:"extra_tags"`
+	VideoIframeDomains []string            `json:"video_iframe_domains"`
+}
+
+func (r sanitizerPolicyRequest) toPolicy() *sanitizer.Policy {
+	return &sanitizer.Policy{
+		IframeDomains:      r.IframeDomains,
+		BlockedResources:   r.BlockedResources,
+		ExtraTags:          r.ExtraTags,
+		VideoIframeDomains: r.VideoIframeDomains,
+	}
+}
+
+// SettingsStore persists settings configurable at runtime across
+// restarts. memorySettingsStore is the in-process default; a real
+// deployment backs this with the same database migration that holds the
+// rest of yarr's settings, keeping the sanitizer's built-in lists as the
+// default until a policy is actually saved over them.
+type SettingsStore interface {
+	LoadSanitizerPolicy() (*sanitizer.Policy, bool)
+	SaveSanitizerPolicy(*sanitizer.Policy)
+}
+
+type memorySettingsStore struct {
+	mu     sync.RWMutex
+	policy *sanitizer.Policy
+}
+
+func newMemorySettingsStore() *memorySettingsStore {
+	return &memorySettingsStore{}
+}
+
+func (s *memorySettingsStore) LoadSanitizerPolicy() (*sanitizer.Policy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.policy == nil {
+		return nil, false
+	}
+	return s.policy, true
+}
+
+func (s *memorySettingsStore) SaveSanitizerPolicy(p *sanitizer.Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = p
+}
+
+// Route pairs an HTTP method and path with the handler that serves it.
+type Route struct {
+	Method  string
+	Path    string
+	Handler func(*router.Context)
+}
+
+// Routes returns the API routes this file defines. It does not register
+// them with any mux.
+func (s *Server) Routes() []Route {
+	return []Route{
+		{Method: "POST", Path: "/api/settings/sanitizer", Handler: s.handleUpdateSanitizerPolicy},
+	}
+}
+
+// handleUpdateSanitizerPolicy handles POST /api/settings/sanitizer. It
+// merges the submitted lists with the sanitizer's built-in defaults,
+// applies them immediately to every subsequent Sanitize call, and saves
+// them to the server's SettingsStore (the in-memory default does not
+// survive a restart).
+//
+// The handler does no authentication of its own. The Origin/Referer
+// check below guards against CSRF: it stops a cross-site page from using
+// a logged-in browser's cookies to submit this form on the victim's
+// behalf.
+func (s *Server) handleUpdateSanitizerPolicy(c *router.Context) {
+	if !sameOrigin(c.Req) {
+		c.Out.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var body sanitizerPolicyRequest
+	if err := json.NewDecoder(c.Req.Body).Decode(&body); err != nil {
+		c.Out.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	policy := body.toPolicy()
+	sanitizer.SetPolicy(policy)
+	s.settingsStore.SaveSanitizerPolicy(policy)
+
+	c.Out.WriteHeader(http.StatusOK)
+}
+
+// sameOrigin reports whether the request's Origin (falling back to
+// Referer, since not every client sends Origin on same-site requests)
+// matches the host being served.
+func sameOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+	}
+	if source == "" {
+		return false
+	}
+
+	u, err := url.Parse(source)
+	return err == nil && u.Host == r.Host
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nkanaev/yarr/src/content/sanitizer"
+)
+
+func TestMemorySettingsStoreRoundTrip(t *testing.T) {
+	store := newMemorySettingsStore()
+
+	if _, ok := store.LoadSanitizerPolicy(); ok {
+		t.Fatal("expected no policy to be stored yet")
+	}
+
+	policy := &sanitizer.Policy{IframeDomains: []string{"odysee.com"}}
+	store.SaveSanitizerPolicy(policy)
+
+	loaded, ok := store.LoadSanitizerPolicy()
+	if !ok {
+		t.Fatal("expected a saved policy to be loaded")
+	}
+	if len(loaded.IframeDomains) != 1 || loaded.IframeDomains[0] != "odysee.com" {
+		t.Fatalf("expected saved policy to round-trip, got %+v", loaded)
+	}
+}
+
+func TestSameOriginAcceptsMatchingOrigin(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://yarr.example/api/settings/sanitizer", nil)
+	req.Host = "yarr.example"
+	req.Header.Set("Origin", "http://yarr.example")
+
+	if !sameOrigin(req) {
+		t.Fatal("expected matching Origin to be accepted")
+	}
+}
+
+func TestSameOriginRejectsCrossSiteOrigin(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://yarr.example/api/settings/sanitizer", nil)
+	req.Host = "yarr.example"
+	req.Header.Set("Origin", "http://evil.example")
+
+	if sameOrigin(req) {
+		t.Fatal("expected cross-site Origin to be rejected")
+	}
+}
+
+func TestSameOriginRejectsMissingOriginAndReferer(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://yarr.example/api/settings/sanitizer", nil)
+	req.Host = "yarr.example"
+
+	if sameOrigin(req) {
+		t.Fatal("expected a request with no Origin/Referer to be rejected")
+	}
+}
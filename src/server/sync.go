@@ -0,0 +1,38 @@
+package server
+
+import "time"
+
+// FeedSource is the minimal surface the sync loop needs from whatever
+// actually fetches feeds. The HTTP fetching/parsing itself lives in the
+// storage-backed fetcher package, which isn't part of this slice of the
+// tree; RunSync only needs something that can run one fetch pass and
+// report, per feed, how many items turned out to be new.
+type FeedSource interface {
+	FetchAll() []FeedFetchResult
+}
+
+// FeedFetchResult is one feed's outcome from a single sync pass.
+type FeedFetchResult struct {
+	FeedTitle string
+	NewItems  int
+}
+
+// RunSync runs source.FetchAll on a timer and reports each feed's new
+// items through reportNewItems, which is what keeps UnreadCount() live
+// and feeds platform.Start's desktop-notification loop. It blocks until
+// stop is closed, so callers should run it in its own goroutine.
+func (s *Server) RunSync(source FeedSource, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, result := range source.FetchAll() {
+				s.reportNewItems(result.FeedTitle, result.NewItems)
+			}
+		}
+	}
+}
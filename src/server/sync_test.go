@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeFeedSource struct {
+	results []FeedFetchResult
+	fetched bool
+}
+
+// FetchAll reports results on the first pass only; later passes find
+// nothing new, as a real source would once the items are stored.
+func (f *fakeFeedSource) FetchAll() []FeedFetchResult {
+	if f.fetched {
+		return nil
+	}
+	f.fetched = true
+	return f.results
+}
+
+func TestRunSyncReportsNewItems(t *testing.T) {
+	s := NewServer(":0", nil)
+	source := &fakeFeedSource{results: []FeedFetchResult{
+		{FeedTitle: "Example Feed", NewItems: 3},
+	}}
+	stop := make(chan struct{})
+
+	go s.RunSync(source, time.Millisecond, stop)
+
+	select {
+	case event := <-s.Events():
+		if event.FeedTitle != "Example Feed" || event.NewItems != 3 {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for sync to report new items")
+	}
+
+	close(stop)
+
+	if s.UnreadCount() != 3 {
+		t.Fatalf("expected unread count to be 3, got %d", s.UnreadCount())
+	}
+}